@@ -6,39 +6,43 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/oteldemo/workers/internal/config"
 	"github.com/oteldemo/workers/internal/dns"
+	"github.com/oteldemo/workers/internal/leveldbqueue"
+	"github.com/oteldemo/workers/internal/queue"
 	"github.com/oteldemo/workers/internal/redis"
 	"github.com/oteldemo/workers/internal/telemetry"
 	"github.com/oteldemo/workers/internal/worker"
 )
 
+// newQueueBackend selects the queue backend from the scheme of cfg.QueueURI.
+func newQueueBackend(cfg *config.Config) queue.Backend {
+	if strings.HasPrefix(cfg.QueueURI, "leveldb://") {
+		backend, err := leveldbqueue.New(cfg)
+		if err != nil {
+			log.Fatalf("Failed to open leveldb queue: %v", err)
+		}
+		return backend
+	}
+	return redis.NewClient(cfg)
+}
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
 
-	// Initialize OpenTelemetry Tracing
-	shutdownTracer, err := telemetry.InitTracer(cfg)
-	if err != nil {
-		log.Fatalf("Failed to initialize tracer: %v", err)
-	}
-	defer func() {
-		if err := shutdownTracer(context.Background()); err != nil {
-			log.Printf("Error shutting down tracer: %v", err)
-		}
-	}()
-
-	// Initialize OpenTelemetry Logging
-	shutdownLogger, _, err := telemetry.InitLogger(cfg)
+	// Initialize OpenTelemetry (tracer + logger + meter)
+	shutdownTelemetry, err := telemetry.Init(context.Background(), cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize logger: %v", err)
+		log.Fatalf("Failed to initialize telemetry: %v", err)
 	}
 	defer func() {
-		if err := shutdownLogger(context.Background()); err != nil {
-			log.Printf("Error shutting down logger: %v", err)
+		if err := shutdownTelemetry(context.Background()); err != nil {
+			log.Printf("Error shutting down telemetry: %v", err)
 		}
 	}()
 
@@ -46,15 +50,15 @@ func main() {
 		"location", cfg.Location,
 	)
 
-	// Initialize Redis client
-	redisClient := redis.NewClient(cfg.RedisURL)
-	defer redisClient.Close()
+	// Initialize queue backend
+	queueBackend := newQueueBackend(cfg)
+	defer queueBackend.Close()
 
 	// Initialize DNS resolver
 	dnsResolver := dns.NewResolver(cfg)
 
 	// Create worker
-	w := worker.NewWorker(cfg, redisClient, dnsResolver)
+	w := worker.NewWorker(cfg, queueBackend, dnsResolver)
 
 	// Start worker
 	ctx, cancel := context.WithCancel(context.Background())
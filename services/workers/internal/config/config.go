@@ -3,19 +3,50 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds application configuration
 type Config struct {
 	Location                   string
-	RedisURL                   string
+	HTTPPort                   string        // Port the health/status HTTP server listens on
+	QueueURI                   string        // Selects the queue backend: redis://, redis+sentinel://, redis+cluster://, or leveldb://
+	RedisMasterName            string        // Sentinel master name, used when QueueURI has a redis+sentinel:// scheme
+	RedisSentinelPassword      string        // Password for authenticating against the Sentinel nodes themselves
+	RedisRouteByLatency        bool          // Route read-only commands to the replica with the lowest latency (Sentinel/Cluster)
+	RedisNodeReadTimeout       time.Duration // Per-node read timeout for standalone, Sentinel, and Cluster connections
+	RedisOtelInstrumentation   bool          // Trace/meter every Redis command via redisotel; disabled by OTEL_REDIS_INSTRUMENTATION=off
+	OtelEnabled                bool          // Master on/off switch; disabled by OTEL_SDK_DISABLED=true
 	OtelCollectorEndpoint      string
+	OtelTracesBackend          string            // otlpgrpc (default), otlphttp, jaeger, zipkin, or stdout
+	OtelLogsBackend            string            // otlpgrpc (default), otlphttp, or stdout
+	OtelExporterInsecure       bool              // Skip TLS when talking to the collector/backend
+	OtelExporterCertFile       string            // CA cert for verifying the collector/backend; empty uses the system pool
+	OtelExporterHeaders        map[string]string // Extra headers (e.g. bearer auth) sent with every OTLP export
+	OtelTracesSampler          string            // always_on, always_off, traceidratio, parentbased_always_on, parentbased_always_off, or parentbased_traceidratio
+	OtelTracesSamplerArg       float64           // Sampling ratio used by traceidratio / parentbased_traceidratio
+	OtelLogLevel               string            // Minimum level emitted through the OTel log bridge, per OTEL_LOG_LEVEL
 	ServiceName                string
 	TasksStream                string
 	ResultsStream              string
+	DLQStream                  string // Stream for tasks that exceeded ReclaimMaxDeliveries
 	ConsumerGroup              string
-	ChaosSequentialProbability float64 // Probability (0.0-1.0) of running DNS lookups sequentially instead of concurrently
-	ChaosErrorProbability      float64 // Probability (0.0-1.0) of individual DNS lookups failing with an error
+	ReclaimInterval            time.Duration // How often the reclaim loop scans for stuck pending messages
+	ReclaimMinIdleTime         time.Duration // XAUTOCLAIM/XCLAIM min-idle-time before a pending message is considered stuck
+	ReclaimMaxDeliveries       int64         // Delivery attempts allowed before a message is diverted to the DLQ
+	DNSUpstreamServer          string        // host:port of the resolver to query; empty means use the system resolver
+	DNSTimeout                 time.Duration // Per-query timeout for the upstream DNS server
+	ChaosSequentialProbability float64       // Probability (0.0-1.0) of running DNS lookups sequentially instead of concurrently
+	ChaosErrorProbability      float64       // Probability (0.0-1.0) of individual DNS lookups failing with an error
+}
+
+// locationUpstreamServers maps WORKER_LOCATION values to the DNS server they query,
+// so different locations genuinely observe different DNS paths. Locations not listed
+// here fall back to the system resolver.
+var locationUpstreamServers = map[string]string{
+	"us-east": "8.8.8.8:53",
+	"eu-west": "1.1.1.1:53",
 }
 
 // Load loads configuration from environment variables
@@ -41,14 +72,101 @@ func Load() *Config {
 		}
 	}
 
+	// Per-node read timeout for standalone, Sentinel, and Cluster connections (default
+	// 65s, leaving headroom over the 60s XREADGROUP Block duration)
+	redisNodeReadTimeout := 65 * time.Second
+	if val := os.Getenv("REDIS_NODE_READ_TIMEOUT"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			redisNodeReadTimeout = d
+		}
+	}
+
+	// How often the reclaim loop scans for pending messages abandoned by dead consumers
+	reclaimInterval := 30 * time.Second
+	if val := os.Getenv("RECLAIM_INTERVAL"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			reclaimInterval = d
+		}
+	}
+
+	// Min idle time before a pending message is considered stuck. All workers in a
+	// location share one consumer name, so this can't tell "consumer crashed" apart
+	// from "still processing"; it must comfortably exceed worst-case processing time
+	// (sequential chaos lookups against a DNSTimeout of several seconds each) or the
+	// reclaim loop will XCLAIM and DLQ a task its own consumer is still handling.
+	// Default 5m.
+	reclaimMinIdleTime := 5 * time.Minute
+	if val := os.Getenv("RECLAIM_MIN_IDLE_TIME"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			reclaimMinIdleTime = d
+		}
+	}
+
+	// Delivery attempts allowed before a message is diverted to the DLQ (default 5)
+	reclaimMaxDeliveries := int64(5)
+	if val := os.Getenv("RECLAIM_MAX_DELIVERIES"); val != "" {
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil && n > 0 {
+			reclaimMaxDeliveries = n
+		}
+	}
+
+	dnsTimeout := 5 * time.Second
+	if val := os.Getenv("DNS_TIMEOUT"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil {
+			dnsTimeout = d
+		}
+	}
+
+	// OTEL_REDIS_INSTRUMENTATION uses an explicit "off" sentinel rather than a generic
+	// bool so it reads the same as the OTel collector's own on/off toggles.
+	redisOtelInstrumentation := os.Getenv("OTEL_REDIS_INSTRUMENTATION") != "off"
+
+	// Sampling ratio for traceidratio / parentbased_traceidratio (default: sample everything)
+	otelTracesSamplerArg := 1.0
+	if val := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); val != "" {
+		if p, err := strconv.ParseFloat(val, 64); err == nil && p >= 0.0 && p <= 1.0 {
+			otelTracesSamplerArg = p
+		}
+	}
+
+	// OTEL_EXPORTER_OTLP_PROTOCOL is the spec's generic grpc/http/protobuf switch; it
+	// only picks the default backend, so the more specific OTEL_TRACES_BACKEND /
+	// OTEL_LOGS_BACKEND still win when set explicitly.
+	defaultOtelBackend := "otlpgrpc"
+	switch os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL") {
+	case "http/protobuf", "http":
+		defaultOtelBackend = "otlphttp"
+	}
+
 	return &Config{
 		Location:                   location,
-		RedisURL:                   getEnv("REDIS_URL", "redis://redis:6379"),
+		HTTPPort:                   getEnv("HTTP_PORT", "8080"),
+		QueueURI:                   getEnv("QUEUE_URI", "redis://redis:6379"),
+		RedisMasterName:            getEnv("REDIS_MASTER_NAME", ""),
+		RedisSentinelPassword:      getEnv("REDIS_SENTINEL_PASSWORD", ""),
+		RedisRouteByLatency:        getEnvBool("REDIS_ROUTE_BY_LATENCY", false),
+		RedisNodeReadTimeout:       redisNodeReadTimeout,
+		RedisOtelInstrumentation:   redisOtelInstrumentation,
+		OtelEnabled:                !getEnvBool("OTEL_SDK_DISABLED", false),
 		OtelCollectorEndpoint:      getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "worker-collector:4317"),
+		OtelTracesBackend:          getEnv("OTEL_TRACES_BACKEND", defaultOtelBackend),
+		OtelLogsBackend:            getEnv("OTEL_LOGS_BACKEND", defaultOtelBackend),
+		OtelExporterInsecure:       getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		OtelExporterCertFile:       getEnv("OTEL_EXPORTER_OTLP_CERTIFICATE", ""),
+		OtelExporterHeaders:        parseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		OtelTracesSampler:          getEnv("OTEL_TRACES_SAMPLER", "always_on"),
+		OtelTracesSamplerArg:       otelTracesSamplerArg,
+		OtelLogLevel:               getEnv("OTEL_LOG_LEVEL", "info"),
 		ServiceName:                getEnv("OTEL_SERVICE_NAME", "dns-worker-"+location),
 		TasksStream:                "dns:tasks",
 		ResultsStream:              "dns:results",
-		ConsumerGroup:              "workers-" + location,  // Each location has its own consumer group for fan-out
+		DLQStream:                  getEnv("DLQ_STREAM", "dns:tasks:dlq"),
+		ConsumerGroup:              "workers-" + location, // Each location has its own consumer group for fan-out
+		ReclaimInterval:            reclaimInterval,
+		ReclaimMinIdleTime:         reclaimMinIdleTime,
+		ReclaimMaxDeliveries:       reclaimMaxDeliveries,
+		DNSUpstreamServer:          getEnv("DNS_UPSTREAM_SERVER", locationUpstreamServers[location]),
+		DNSTimeout:                 dnsTimeout,
 		ChaosSequentialProbability: chaosSequentialProb,
 		ChaosErrorProbability:      chaosErrorProb,
 	}
@@ -61,3 +179,33 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+// parseHeaders parses the OTEL_EXPORTER_OTLP_HEADERS format: comma-separated
+// key=value pairs, e.g. "api-key=secret,x-tenant=demo". Malformed pairs are skipped.
+func parseHeaders(value string) map[string]string {
+	if value == "" {
+		return nil
+	}
+
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return headers
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
-	"os/exec"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/miekg/dns"
+
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -20,20 +21,43 @@ var tracer = otel.Tracer("dns-resolver")
 
 // Resolver handles DNS lookups
 type Resolver struct {
-	cfg *config.Config
+	cfg    *config.Config
+	server string // host:port of the upstream DNS server to query
 }
 
-// NewResolver creates a new DNS resolver
+// NewResolver creates a new DNS resolver. The upstream server is taken from
+// cfg.DNSUpstreamServer; if that's empty, the system resolver configuration
+// (/etc/resolv.conf) is used instead.
 func NewResolver(cfg *config.Config) *Resolver {
-	return &Resolver{cfg: cfg}
+	server := cfg.DNSUpstreamServer
+	if server == "" {
+		server = systemResolverServer()
+	}
+	return &Resolver{cfg: cfg, server: server}
+}
+
+// systemResolverServer reads the first nameserver out of /etc/resolv.conf, falling
+// back to the loopback resolver if that can't be read.
+func systemResolverServer() string {
+	conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil || len(conf.Servers) == 0 {
+		return "127.0.0.1:53"
+	}
+	return conf.Servers[0] + ":" + conf.Port
 }
 
 // LookupResult represents the result of a DNS lookup
 type LookupResult struct {
-	RecordType string        `json:"record_type"`
-	Records    []string      `json:"records"`
-	Duration   time.Duration `json:"duration_ms"`
-	Error      string        `json:"error,omitempty"`
+	RecordType    string        `json:"record_type"`
+	Records       []string      `json:"records"`
+	Duration      time.Duration `json:"duration_ms"`
+	Error         string        `json:"error,omitempty"`
+	TTL           uint32        `json:"ttl,omitempty"`
+	ResponseCode  string        `json:"response_code,omitempty"`
+	AnswerCount   int           `json:"answer_count"`
+	ResponseBytes int           `json:"response_bytes"`
+	Truncated     bool          `json:"truncated"`
+	Authoritative bool          `json:"authoritative"`
 }
 
 // LookupAllRecords performs DNS lookups for multiple record types
@@ -95,6 +119,8 @@ func (r *Resolver) lookupConcurrent(ctx context.Context, domain string, recordTy
 				trace.WithAttributes(
 					attribute.String("dns.record_type", rt),
 					attribute.String("dns.domain", domain),
+					attribute.String("dns.query.name", domain),
+					attribute.String("dns.query.type", rt),
 				),
 			)
 			defer lookupSpan.End()
@@ -103,21 +129,7 @@ func (r *Resolver) lookupConcurrent(ctx context.Context, domain string, recordTy
 			result := r.lookupRecord(domain, rt)
 
 			// Add span attributes based on result
-			lookupSpan.SetAttributes(
-				attribute.Int("dns.records.count", len(result.Records)),
-				attribute.Int64("dns.duration_ms", result.Duration.Milliseconds()),
-			)
-
-			if result.Error != "" {
-				lookupSpan.SetAttributes(
-					attribute.Bool("error", true),
-					attribute.String("error.message", result.Error),
-				)
-				// Mark chaos-injected errors
-				if strings.HasPrefix(result.Error, "chaos engineering:") {
-					lookupSpan.SetAttributes(attribute.Bool("chaos.injected_error", true))
-				}
-			}
+			annotateSpan(lookupSpan, result)
 
 			// Store result (thread-safe)
 			mu.Lock()
@@ -143,6 +155,8 @@ func (r *Resolver) lookupSequential(ctx context.Context, domain string, recordTy
 			trace.WithAttributes(
 				attribute.String("dns.record_type", rt),
 				attribute.String("dns.domain", domain),
+				attribute.String("dns.query.name", domain),
+				attribute.String("dns.query.type", rt),
 			),
 		)
 
@@ -150,21 +164,7 @@ func (r *Resolver) lookupSequential(ctx context.Context, domain string, recordTy
 		result := r.lookupRecord(domain, rt)
 
 		// Add span attributes based on result
-		lookupSpan.SetAttributes(
-			attribute.Int("dns.records.count", len(result.Records)),
-			attribute.Int64("dns.duration_ms", result.Duration.Milliseconds()),
-		)
-
-		if result.Error != "" {
-			lookupSpan.SetAttributes(
-				attribute.Bool("error", true),
-				attribute.String("error.message", result.Error),
-			)
-			// Mark chaos-injected errors
-			if strings.HasPrefix(result.Error, "chaos engineering:") {
-				lookupSpan.SetAttributes(attribute.Bool("chaos.injected_error", true))
-			}
-		}
+		annotateSpan(lookupSpan, result)
 
 		lookupSpan.End()
 
@@ -175,7 +175,31 @@ func (r *Resolver) lookupSequential(ctx context.Context, domain string, recordTy
 	return results
 }
 
-// lookupRecord performs a DNS lookup for a specific record type using dig
+// annotateSpan records the outcome of a single lookupRecord call on its span.
+func annotateSpan(span trace.Span, result LookupResult) {
+	span.SetAttributes(
+		attribute.Int("dns.records.count", len(result.Records)),
+		attribute.Int64("dns.duration_ms", result.Duration.Milliseconds()),
+		attribute.String("dns.response.code", result.ResponseCode),
+		attribute.Int64("dns.response.ttl", int64(result.TTL)),
+		attribute.Int("dns.response.size", result.ResponseBytes),
+		attribute.Bool("dns.response.truncated", result.Truncated),
+	)
+
+	if result.Error != "" {
+		span.SetAttributes(
+			attribute.Bool("error", true),
+			attribute.String("error.message", result.Error),
+		)
+		// Mark chaos-injected errors
+		if strings.HasPrefix(result.Error, "chaos engineering:") {
+			span.SetAttributes(attribute.Bool("chaos.injected_error", true))
+		}
+	}
+}
+
+// lookupRecord performs a real DNS query for a specific record type against
+// r.server, falling back from UDP to TCP if the response comes back truncated.
 func (r *Resolver) lookupRecord(domain, recordType string) LookupResult {
 	start := time.Now()
 
@@ -191,25 +215,85 @@ func (r *Resolver) lookupRecord(domain, recordType string) LookupResult {
 		return result
 	}
 
-	// Execute dig command
-	cmd := exec.Command("dig", "+short", domain, recordType)
-	output, err := cmd.CombinedOutput()
+	qtype, ok := dns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		result.Duration = time.Since(start)
+		result.Error = fmt.Sprintf("unsupported DNS record type: %s", recordType)
+		return result
+	}
+
+	queryName := domain
+	if qtype == dns.TypePTR {
+		reverseName, err := dns.ReverseAddr(domain)
+		if err != nil {
+			result.Duration = time.Since(start)
+			result.Error = fmt.Sprintf("invalid address for PTR lookup: %v", err)
+			return result
+		}
+		queryName = reverseName
+	}
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(queryName), qtype)
+	msg.RecursionDesired = true
+
+	client := &dns.Client{Timeout: r.cfg.DNSTimeout}
+	resp, _, err := client.Exchange(msg, r.server)
+	if err == nil && resp.Truncated {
+		// The UDP response didn't fit - retry over TCP to get the full answer
+		tcpClient := &dns.Client{Net: "tcp", Timeout: r.cfg.DNSTimeout}
+		resp, _, err = tcpClient.Exchange(msg, r.server)
+	}
 
 	result.Duration = time.Since(start)
 
 	if err != nil {
-		result.Error = fmt.Sprintf("dig command failed: %v", err)
+		result.Error = fmt.Sprintf("dns query failed: %v", err)
+		return result
+	}
+
+	result.ResponseCode = dns.RcodeToString[resp.Rcode]
+	result.AnswerCount = len(resp.Answer)
+	result.ResponseBytes = resp.Len()
+	result.Truncated = resp.Truncated
+	result.Authoritative = resp.Authoritative
+
+	if resp.Rcode != dns.RcodeSuccess {
+		result.Error = fmt.Sprintf("dns server returned %s", result.ResponseCode)
 		return result
 	}
 
-	// Parse output
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" {
-			result.Records = append(result.Records, line)
+	for _, rr := range resp.Answer {
+		result.Records = append(result.Records, recordValue(rr))
+		if result.TTL == 0 {
+			result.TTL = rr.Header().Ttl
 		}
 	}
 
 	return result
 }
+
+// recordValue extracts the human-readable value of an answer RR, e.g. the address
+// for an A record or the target for a CNAME, instead of its full zone-file form.
+func recordValue(rr dns.RR) string {
+	switch v := rr.(type) {
+	case *dns.A:
+		return v.A.String()
+	case *dns.AAAA:
+		return v.AAAA.String()
+	case *dns.CNAME:
+		return v.Target
+	case *dns.TXT:
+		return strings.Join(v.Txt, " ")
+	case *dns.MX:
+		return fmt.Sprintf("%d %s", v.Preference, v.Mx)
+	case *dns.NS:
+		return v.Ns
+	case *dns.SRV:
+		return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+	case *dns.PTR:
+		return v.Ptr
+	default:
+		return rr.String()
+	}
+}
@@ -0,0 +1,336 @@
+// Package leveldbqueue is an embedded queue.Backend backed by LevelDB, so developers
+// can run the worker without standing up Redis and CI can exercise the full
+// task/result flow deterministically.
+package leveldbqueue
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/oteldemo/workers/internal/config"
+	"github.com/oteldemo/workers/internal/queue"
+)
+
+const (
+	pollInterval = 200 * time.Millisecond
+	readNewWait  = 5 * time.Second
+)
+
+// Backend is a single-process queue.Backend. Each stream is an append-only log of
+// JSON-encoded messages keyed by a monotonically increasing ID; per-group cursors
+// and pending-entry records reproduce the subset of Redis Streams semantics the
+// worker depends on (EnsureGroup/ReadNew/ReadPending/Ack/Claim).
+type Backend struct {
+	db *leveldb.DB
+	mu sync.Mutex // serializes the read-modify-write sequences below
+}
+
+var _ queue.Backend = (*Backend)(nil)
+
+// New opens (or creates) a LevelDB database at the path encoded in a leveldb:// URI,
+// e.g. leveldb:///var/lib/oteldemo/queue.
+func New(cfg *config.Config) (*Backend, error) {
+	path := strings.TrimPrefix(cfg.QueueURI, "leveldb://")
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leveldb queue at %s: %w", path, err)
+	}
+	return &Backend{db: db}, nil
+}
+
+// Close releases the underlying LevelDB handle.
+func (b *Backend) Close() error {
+	return b.db.Close()
+}
+
+// IsHealthy reports whether the database is still open and readable.
+func (b *Backend) IsHealthy(ctx context.Context) bool {
+	_, err := b.db.Get([]byte("__healthcheck__"), nil)
+	return err == nil || err == leveldb.ErrNotFound
+}
+
+type pendingEntry struct {
+	Consumer      string `json:"consumer"`
+	DeliveryCount int64  `json:"delivery_count"`
+	DeliveredAt   int64  `json:"delivered_at"` // UnixNano
+}
+
+func msgKey(stream string, id uint64) string    { return fmt.Sprintf("msg/%s/%020d", stream, id) }
+func seqKey(stream string) string               { return "seq/" + stream }
+func cursorKey(stream, group string) string     { return fmt.Sprintf("cursor/%s/%s", stream, group) }
+func pendingPrefix(stream, group string) string { return fmt.Sprintf("pending/%s/%s/", stream, group) }
+func pendingKey(stream, group string, id uint64) string {
+	return fmt.Sprintf("%s%020d", pendingPrefix(stream, group), id)
+}
+
+func encodeID(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, id)
+	return buf
+}
+
+func decodeID(buf []byte) uint64 {
+	return binary.BigEndian.Uint64(buf)
+}
+
+// EnsureGroup creates the consumer group's cursor if it doesn't exist yet, starting
+// it at the current end of the stream so the group only sees new messages - the
+// same "$" semantics XGROUP CREATE uses in Redis.
+func (b *Backend) EnsureGroup(ctx context.Context, stream, group string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := []byte(cursorKey(stream, group))
+	if _, err := b.db.Get(key, nil); err == nil {
+		return nil
+	} else if err != leveldb.ErrNotFound {
+		return err
+	}
+
+	seq, err := b.currentSeqLocked(stream)
+	if err != nil {
+		return err
+	}
+	return b.db.Put(key, encodeID(seq), nil)
+}
+
+func (b *Backend) currentSeqLocked(stream string) (uint64, error) {
+	val, err := b.db.Get([]byte(seqKey(stream)), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return decodeID(val), nil
+}
+
+func (b *Backend) cursorLocked(stream, group string) (uint64, error) {
+	val, err := b.db.Get([]byte(cursorKey(stream, group)), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return decodeID(val), nil
+}
+
+// PublishResult marshals data to JSON and appends it as a new message on stream.
+func (b *Backend) PublishResult(ctx context.Context, stream string, data interface{}) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	entry, err := json.Marshal(map[string]interface{}{"data": string(payload)})
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seq, err := b.currentSeqLocked(stream)
+	if err != nil {
+		return "", err
+	}
+	id := seq + 1
+
+	batch := new(leveldb.Batch)
+	batch.Put([]byte(msgKey(stream, id)), entry)
+	batch.Put([]byte(seqKey(stream)), encodeID(id))
+	if err := b.db.Write(batch, nil); err != nil {
+		return "", err
+	}
+
+	return strconv.FormatUint(id, 10), nil
+}
+
+// ReadNew waits up to readNewWait for messages newer than group's cursor, polling
+// every pollInterval - there's no long-poll primitive in LevelDB, so this emulates
+// the Redis client's blocking XREADGROUP closely enough to avoid busy-looping.
+func (b *Backend) ReadNew(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	deadline := time.Now().Add(readNewWait)
+	for {
+		messages, err := b.claimNewLocked(stream, group, consumer)
+		if err != nil || len(messages) > 0 {
+			return messages, err
+		}
+		if time.Now().After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (b *Backend) claimNewLocked(stream, group, consumer string) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cursor, err := b.cursorLocked(stream, group)
+	if err != nil {
+		return nil, err
+	}
+	seq, err := b.currentSeqLocked(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []queue.Message
+	batch := new(leveldb.Batch)
+	for id := cursor + 1; id <= seq; id++ {
+		raw, err := b.db.Get([]byte(msgKey(stream, id)), nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+		messages = append(messages, queue.Message{ID: strconv.FormatUint(id, 10), Data: data, DeliveryCount: 1})
+
+		entry, err := json.Marshal(pendingEntry{Consumer: consumer, DeliveryCount: 1, DeliveredAt: time.Now().UnixNano()})
+		if err != nil {
+			return nil, err
+		}
+		batch.Put([]byte(pendingKey(stream, group, id)), entry)
+	}
+
+	if len(messages) == 0 {
+		return nil, nil
+	}
+
+	batch.Put([]byte(cursorKey(stream, group)), encodeID(seq))
+	if err := b.db.Write(batch, nil); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// ReadPending returns messages already delivered to consumer but not yet acked.
+func (b *Backend) ReadPending(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prefix := pendingPrefix(stream, group)
+	iter := b.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+
+	var messages []queue.Message
+	for iter.Next() {
+		var entry pendingEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		if entry.Consumer != consumer {
+			continue
+		}
+
+		id, err := strconv.ParseUint(strings.TrimPrefix(string(iter.Key()), prefix), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := b.db.Get([]byte(msgKey(stream, id)), nil)
+		if err != nil {
+			continue // message record can't be read; skip this pending entry
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, queue.Message{ID: strconv.FormatUint(id, 10), Data: data, DeliveryCount: entry.DeliveryCount})
+	}
+	return messages, iter.Error()
+}
+
+// Ack removes messageID from group's pending set.
+func (b *Backend) Ack(ctx context.Context, stream, group, messageID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id, err := strconv.ParseUint(messageID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return b.db.Delete([]byte(pendingKey(stream, group, id)), nil)
+}
+
+// Claim reassigns pending entries idle for at least minIdle to consumer, bumping
+// their delivery count so callers can decide whether to divert them to a DLQ.
+func (b *Backend) Claim(ctx context.Context, stream, group, consumer string, minIdle time.Duration) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prefix := pendingPrefix(stream, group)
+	iter := b.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+
+	now := time.Now()
+	var messages []queue.Message
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		var entry pendingEntry
+		if err := json.Unmarshal(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		if now.Sub(time.Unix(0, entry.DeliveredAt)) < minIdle {
+			continue
+		}
+
+		key := append([]byte(nil), iter.Key()...)
+		id, err := strconv.ParseUint(strings.TrimPrefix(string(key), prefix), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := b.db.Get([]byte(msgKey(stream, id)), nil)
+		if err != nil {
+			continue
+		}
+		var data map[string]interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, err
+		}
+
+		entry.Consumer = consumer
+		entry.DeliveryCount++
+		entry.DeliveredAt = now.UnixNano()
+		updated, err := json.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		batch.Put(key, updated)
+
+		messages = append(messages, queue.Message{ID: strconv.FormatUint(id, 10), Data: data, DeliveryCount: entry.DeliveryCount})
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	if len(messages) > 0 {
+		if err := b.db.Write(batch, nil); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,151 @@
+// Package memqueue is an in-memory queue.Backend with no external dependencies,
+// used to exercise worker.processMessage in tests without Redis or LevelDB. The
+// worker binary does not select it; it only chooses between Redis and LevelDB.
+package memqueue
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/oteldemo/workers/internal/queue"
+)
+
+type pendingEntry struct {
+	message     queue.Message
+	consumer    string
+	deliveredAt time.Time
+}
+
+// Backend stores each stream as an in-process slice of messages.
+type Backend struct {
+	mu      sync.Mutex
+	streams map[string][]queue.Message
+	cursors map[string]int                      // "stream/group" -> next unread index into streams[stream]
+	pending map[string]map[string]*pendingEntry // "stream/group" -> message ID -> pending entry
+	nextID  int
+}
+
+var _ queue.Backend = (*Backend)(nil)
+
+// New creates an empty in-memory backend.
+func New() *Backend {
+	return &Backend{
+		streams: make(map[string][]queue.Message),
+		cursors: make(map[string]int),
+		pending: make(map[string]map[string]*pendingEntry),
+	}
+}
+
+func (b *Backend) Close() error                       { return nil }
+func (b *Backend) IsHealthy(ctx context.Context) bool { return true }
+
+func groupKey(stream, group string) string { return stream + "/" + group }
+
+func (b *Backend) pendingMapLocked(key string) map[string]*pendingEntry {
+	if b.pending[key] == nil {
+		b.pending[key] = make(map[string]*pendingEntry)
+	}
+	return b.pending[key]
+}
+
+// EnsureGroup starts group's cursor at the current end of stream, so it only sees
+// new messages - the same "$" semantics XGROUP CREATE uses in Redis.
+func (b *Backend) EnsureGroup(ctx context.Context, stream, group string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := groupKey(stream, group)
+	if _, ok := b.cursors[key]; !ok {
+		b.cursors[key] = len(b.streams[stream])
+	}
+	b.pendingMapLocked(key)
+	return nil
+}
+
+// PublishResult marshals data to JSON and appends it as a new message on stream.
+func (b *Backend) PublishResult(ctx context.Context, stream string, data interface{}) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := strconv.Itoa(b.nextID)
+	b.streams[stream] = append(b.streams[stream], queue.Message{
+		ID:   id,
+		Data: map[string]interface{}{"data": string(payload)},
+	})
+	return id, nil
+}
+
+// ReadNew returns messages appended to stream since group last read it.
+func (b *Backend) ReadNew(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := groupKey(stream, group)
+	cursor := b.cursors[key]
+	all := b.streams[stream]
+	if cursor >= len(all) {
+		return nil, nil
+	}
+
+	fresh := append([]queue.Message(nil), all[cursor:]...)
+	b.cursors[key] = len(all)
+
+	pending := b.pendingMapLocked(key)
+	for i := range fresh {
+		fresh[i].DeliveryCount = 1
+		pending[fresh[i].ID] = &pendingEntry{message: fresh[i], consumer: consumer, deliveredAt: time.Now()}
+	}
+	return fresh, nil
+}
+
+// ReadPending returns messages already delivered to consumer but not yet acked.
+func (b *Backend) ReadPending(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []queue.Message
+	for _, entry := range b.pendingMapLocked(groupKey(stream, group)) {
+		if entry.consumer == consumer {
+			messages = append(messages, entry.message)
+		}
+	}
+	return messages, nil
+}
+
+// Ack removes messageID from group's pending set.
+func (b *Backend) Ack(ctx context.Context, stream, group, messageID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.pendingMapLocked(groupKey(stream, group)), messageID)
+	return nil
+}
+
+// Claim reassigns pending entries idle for at least minIdle to consumer, bumping
+// their delivery count so callers can decide whether to divert them to a DLQ.
+func (b *Backend) Claim(ctx context.Context, stream, group, consumer string, minIdle time.Duration) ([]queue.Message, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	var messages []queue.Message
+	for _, entry := range b.pendingMapLocked(groupKey(stream, group)) {
+		if now.Sub(entry.deliveredAt) < minIdle {
+			continue
+		}
+		entry.consumer = consumer
+		entry.deliveredAt = now
+		entry.message.DeliveryCount++
+		messages = append(messages, entry.message)
+	}
+	return messages, nil
+}
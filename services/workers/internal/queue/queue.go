@@ -0,0 +1,55 @@
+// Package queue abstracts the durable task/result queue the worker runs on, so the
+// same worker code can run against Redis Streams, an embedded LevelDB queue, or an
+// in-memory queue for tests without any change to worker logic.
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// Message is a single queued task or result, independent of the backend storing it.
+type Message struct {
+	ID            string
+	Data          map[string]interface{}
+	DeliveryCount int64 // Number of times this message has been delivered to a consumer
+}
+
+// Backend is the durable queue the worker reads tasks from and publishes results to.
+// Implementations exist for Redis Streams (redis://, redis+sentinel://,
+// redis+cluster://), an embedded LevelDB queue (leveldb://) for offline/dev use, and
+// an in-memory queue for unit tests.
+type Backend interface {
+	// EnsureGroup creates the consumer group for stream if it doesn't already exist.
+	EnsureGroup(ctx context.Context, stream, group string) error
+
+	// ReadPending returns messages already delivered to consumer but not yet
+	// acknowledged, e.g. left over from a previous run that crashed before acking.
+	ReadPending(ctx context.Context, stream, group, consumer string) ([]Message, error)
+
+	// ReadNew reads new, previously-undelivered messages from stream for consumer.
+	ReadNew(ctx context.Context, stream, group, consumer string) ([]Message, error)
+
+	// Ack acknowledges a message, removing it from the pending list.
+	Ack(ctx context.Context, stream, group, messageID string) error
+
+	// Claim reassigns messages that have been pending for at least minIdle to
+	// consumer, stealing work left behind by a consumer that died before acking.
+	Claim(ctx context.Context, stream, group, consumer string, minIdle time.Duration) ([]Message, error)
+
+	// PublishResult marshals data to JSON and appends it to stream.
+	PublishResult(ctx context.Context, stream string, data interface{}) (string, error)
+
+	// IsHealthy reports whether the backend can currently be reached.
+	IsHealthy(ctx context.Context) bool
+
+	// Close releases any resources held by the backend.
+	Close() error
+}
+
+// NodeHealthReporter is an optional interface a Backend may implement to report
+// per-node health (e.g. every shard of a Redis Cluster) instead of a single bool.
+type NodeHealthReporter interface {
+	// UnhealthyNodeAddrs returns the addresses of nodes that failed to respond.
+	UnhealthyNodeAddrs(ctx context.Context) []string
+}
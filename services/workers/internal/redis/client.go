@@ -4,37 +4,212 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/oteldemo/workers/internal/config"
+	"github.com/oteldemo/workers/internal/queue"
+)
+
+var (
+	tracer = otel.Tracer("dns-worker/redis")
+	meter  = otel.Meter("dns-worker/redis")
+)
+
+var (
+	readCounter     metric.Int64Counter
+	ackCounter      metric.Int64Counter
+	publishCounter  metric.Int64Counter
+	readDuration    metric.Float64Histogram
+	ackDuration     metric.Float64Histogram
+	publishDuration metric.Float64Histogram
 )
 
-// Client wraps Redis client
+func init() {
+	var err error
+	if readCounter, err = meter.Int64Counter("redis.read.count", metric.WithDescription("Number of Redis stream read operations (pending + new)")); err != nil {
+		log.Printf("Failed to create redis.read.count counter: %v", err)
+	}
+	if ackCounter, err = meter.Int64Counter("redis.ack.count", metric.WithDescription("Number of Redis stream messages acknowledged")); err != nil {
+		log.Printf("Failed to create redis.ack.count counter: %v", err)
+	}
+	if publishCounter, err = meter.Int64Counter("redis.publish.count", metric.WithDescription("Number of messages published to a Redis stream")); err != nil {
+		log.Printf("Failed to create redis.publish.count counter: %v", err)
+	}
+	if readDuration, err = meter.Float64Histogram("redis.read.duration", metric.WithDescription("Duration of Redis stream read operations"), metric.WithUnit("s")); err != nil {
+		log.Printf("Failed to create redis.read.duration histogram: %v", err)
+	}
+	if ackDuration, err = meter.Float64Histogram("redis.ack.duration", metric.WithDescription("Duration of Redis stream ack operations"), metric.WithUnit("s")); err != nil {
+		log.Printf("Failed to create redis.ack.duration histogram: %v", err)
+	}
+	if publishDuration, err = meter.Float64Histogram("redis.publish.duration", metric.WithDescription("Duration of Redis stream publish operations"), metric.WithUnit("s")); err != nil {
+		log.Printf("Failed to create redis.publish.duration histogram: %v", err)
+	}
+}
+
+// instrumentedOp starts a child span named "redis."+op - becoming a child of whatever
+// span is already active on ctx (e.g. process_dns_task) - runs fn, and records its
+// duration/outcome to both the span and the given counter/histogram. counter and
+// duration may be nil for operations request didn't ask metrics for (EnsureGroup, Claim).
+func instrumentedOp(ctx context.Context, op string, attrs []attribute.KeyValue, counter metric.Int64Counter, duration metric.Float64Histogram, fn func(ctx context.Context) error) error {
+	ctx, span := tracer.Start(ctx, "redis."+op, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	start := time.Now()
+	err := fn(ctx)
+	elapsed := time.Since(start).Seconds()
+
+	if counter != nil {
+		counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}
+	if duration != nil {
+		duration.Record(ctx, elapsed, metric.WithAttributes(attrs...))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// Client wraps a Redis handle and implements queue.Backend on top of Redis Streams.
+// The handle may point at a standalone instance, a Sentinel-managed failover group,
+// or a Cluster deployment - redis.UniversalClient is satisfied by all three, so the
+// stream operations below work unchanged regardless of topology.
 type Client struct {
-	client *redis.Client
+	client redis.UniversalClient
+}
+
+var _ queue.Backend = (*Client)(nil)
+
+// NewClient creates a new Redis client. The topology is selected from the scheme of
+// cfg.QueueURI:
+//
+//	redis://host:port                                  -> standalone
+//	redis+sentinel://[user:pass@]host1:port1,host2:port2/master/db -> Sentinel failover group
+//	redis+cluster://[user:pass@]host1:port1,host2:port2             -> Cluster
+func NewClient(cfg *config.Config) *Client {
+	switch {
+	case strings.HasPrefix(cfg.QueueURI, "redis+sentinel://"):
+		return newSentinelClient(cfg)
+	case strings.HasPrefix(cfg.QueueURI, "redis+cluster://"):
+		return newClusterClient(cfg)
+	default:
+		return newStandaloneClient(cfg)
+	}
 }
 
-// NewClient creates a new Redis client
-func NewClient(redisURL string) *Client {
-	// Parse Redis URL
-	opts, err := redis.ParseURL(redisURL)
+func newStandaloneClient(cfg *config.Config) *Client {
+	opts, err := redis.ParseURL(cfg.QueueURI)
 	if err != nil {
 		log.Fatalf("Failed to parse Redis URL: %v", err)
 	}
 
-	// Configure ReadTimeout for long polling with Redis Streams
 	// Must be longer than Block duration in XREADGROUP (60s)
-	opts.ReadTimeout = 65 * time.Second
+	opts.ReadTimeout = cfg.RedisNodeReadTimeout
+
+	return newClientFromUniversal(cfg, redis.NewClient(opts), cfg.QueueURI)
+}
+
+func newSentinelClient(cfg *config.Config) *Client {
+	rest := strings.TrimPrefix(cfg.QueueURI, "redis+sentinel://")
+	user, pass, rest := splitUserinfo(rest)
+	hostsPart, path := splitPath(rest)
+
+	masterName := cfg.RedisMasterName
+	db := 0
+	if path != "" {
+		segments := strings.SplitN(path, "/", 2)
+		if segments[0] != "" {
+			masterName = segments[0]
+		}
+		if len(segments) > 1 {
+			if n, err := strconv.Atoi(segments[1]); err == nil {
+				db = n
+			}
+		}
+	}
+	if masterName == "" {
+		log.Fatalf("redis+sentinel URL %q is missing a master name (expected .../<master>[/<db>])", cfg.QueueURI)
+	}
+
+	opts := &redis.FailoverOptions{
+		MasterName:       masterName,
+		SentinelAddrs:    strings.Split(hostsPart, ","),
+		SentinelPassword: cfg.RedisSentinelPassword,
+		Username:         user,
+		Password:         pass,
+		DB:               db,
+		RouteByLatency:   cfg.RedisRouteByLatency,
+		ReadTimeout:      cfg.RedisNodeReadTimeout,
+	}
+
+	return newClientFromUniversal(cfg, redis.NewFailoverClient(opts), cfg.QueueURI)
+}
+
+func newClusterClient(cfg *config.Config) *Client {
+	rest := strings.TrimPrefix(cfg.QueueURI, "redis+cluster://")
+	user, pass, rest := splitUserinfo(rest)
+	hostsPart, _ := splitPath(rest)
+
+	opts := &redis.ClusterOptions{
+		Addrs:          strings.Split(hostsPart, ","),
+		Username:       user,
+		Password:       pass,
+		RouteByLatency: cfg.RedisRouteByLatency,
+		ReadTimeout:    cfg.RedisNodeReadTimeout,
+	}
 
-	client := redis.NewClient(opts)
+	return newClientFromUniversal(cfg, redis.NewClusterClient(opts), cfg.QueueURI)
+}
+
+// splitUserinfo peels a leading "user:pass@" off rest, if present.
+func splitUserinfo(rest string) (user, pass, remainder string) {
+	idx := strings.Index(rest, "@")
+	if idx == -1 {
+		return "", "", rest
+	}
+	userinfo := rest[:idx]
+	if c := strings.Index(userinfo, ":"); c != -1 {
+		return userinfo[:c], userinfo[c+1:], rest[idx+1:]
+	}
+	return userinfo, "", rest[idx+1:]
+}
 
-	// Test connection
+// splitPath splits a "host1,host2/path" remainder into the comma-separated host list
+// and whatever follows the first "/".
+func splitPath(rest string) (hosts, path string) {
+	if idx := strings.Index(rest, "/"); idx != -1 {
+		return rest[:idx], rest[idx+1:]
+	}
+	return rest, ""
+}
+
+func newClientFromUniversal(cfg *config.Config, client redis.UniversalClient, redisURL string) *Client {
 	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
 	}
 
+	if cfg.RedisOtelInstrumentation {
+		if err := redisotel.InstrumentTracing(client); err != nil {
+			log.Printf("Failed to instrument Redis client with tracing: %v", err)
+		}
+		if err := redisotel.InstrumentMetrics(client); err != nil {
+			log.Printf("Failed to instrument Redis client with metrics: %v", err)
+		}
+	}
+
 	log.Printf("Connected to Redis at %s", redisURL)
 
 	return &Client{client: client}
@@ -47,81 +222,190 @@ func (c *Client) Close() error {
 
 // IsHealthy checks if Redis connection is healthy
 func (c *Client) IsHealthy(ctx context.Context) bool {
-	return c.client.Ping(ctx).Err() == nil
+	return len(c.UnhealthyNodeAddrs(ctx)) == 0
 }
 
-// CreateConsumerGroup creates a consumer group if it doesn't exist
-func (c *Client) CreateConsumerGroup(ctx context.Context, stream, group string) error {
-	err := c.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
-	if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
-		return err
+// UnhealthyNodeAddrs pings every reachable node - each shard in Cluster mode, the
+// current master in standalone/Sentinel mode - and returns the addresses that failed
+// to respond.
+func (c *Client) UnhealthyNodeAddrs(ctx context.Context) []string {
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		var mu sync.Mutex
+		var unhealthy []string
+		_ = cluster.ForEachShard(ctx, func(ctx context.Context, shard *redis.Client) error {
+			if err := shard.Ping(ctx).Err(); err != nil {
+				mu.Lock()
+				unhealthy = append(unhealthy, shard.Options().Addr)
+				mu.Unlock()
+			}
+			return nil
+		})
+		return unhealthy
+	}
+
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return []string{c.primaryAddr()}
 	}
 	return nil
 }
 
-// ReadPendingMessages reads pending messages (delivered but not acknowledged) from a Redis stream
-func (c *Client) ReadPendingMessages(ctx context.Context, stream, group, consumer string) ([]StreamMessage, error) {
-	result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-		Group:    group,
-		Consumer: consumer,
-		Streams:  []string{stream, "0"}, // "0" = pending messages for this consumer
-		Count:    10,
-		Block:    0, // Non-blocking - return immediately
-	}).Result()
+func (c *Client) primaryAddr() string {
+	if standalone, ok := c.client.(*redis.Client); ok {
+		return standalone.Options().Addr
+	}
+	return "unknown"
+}
 
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil // No pending messages
+// EnsureGroup creates a consumer group if it doesn't exist
+func (c *Client) EnsureGroup(ctx context.Context, stream, group string) error {
+	return instrumentedOp(ctx, "ensure_group", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+		attribute.String("messaging.redis.consumer_group", group),
+	}, nil, nil, func(ctx context.Context) error {
+		err := c.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+		if err != nil && !strings.Contains(err.Error(), "BUSYGROUP") {
+			return err
 		}
-		return nil, err
-	}
+		return nil
+	})
+}
 
-	var messages []StreamMessage
-	for _, stream := range result {
-		for _, msg := range stream.Messages {
-			messages = append(messages, StreamMessage{
-				ID:   msg.ID,
-				Data: msg.Values,
-			})
+// ReadPending reads pending messages (delivered but not acknowledged) from a Redis stream
+func (c *Client) ReadPending(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	var messages []queue.Message
+	err := instrumentedOp(ctx, "read_pending", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+		attribute.String("messaging.redis.consumer_group", group),
+	}, readCounter, readDuration, func(ctx context.Context) error {
+		result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{stream, "0"}, // "0" = pending messages for this consumer
+			Count:    10,
+			Block:    0, // Non-blocking - return immediately
+		}).Result()
+
+		if err != nil {
+			if err == redis.Nil {
+				return nil // No pending messages
+			}
+			return err
 		}
-	}
 
-	return messages, nil
+		for _, stream := range result {
+			for _, msg := range stream.Messages {
+				messages = append(messages, queue.Message{
+					ID:   msg.ID,
+					Data: msg.Values,
+				})
+			}
+		}
+		return nil
+	})
+	return messages, err
 }
 
-// ReadFromStream reads new messages from a Redis stream
-func (c *Client) ReadFromStream(ctx context.Context, stream, group, consumer string) ([]StreamMessage, error) {
-	result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-		Group:    group,
-		Consumer: consumer,
-		Streams:  []string{stream, ">"}, // ">" = only new undelivered messages
-		Count:    10,
-		Block:    60000, // 60 seconds - long polling for efficiency
-	}).Result()
+// ReadNew reads new messages from a Redis stream
+func (c *Client) ReadNew(ctx context.Context, stream, group, consumer string) ([]queue.Message, error) {
+	const blockMillis = 60000 // 60 seconds - long polling for efficiency
 
-	if err != nil {
-		if err == redis.Nil {
-			return nil, nil // No messages
+	var messages []queue.Message
+	err := instrumentedOp(ctx, "read_new", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+		attribute.String("messaging.redis.consumer_group", group),
+		attribute.Int64("messaging.redis.block_ms", blockMillis),
+	}, readCounter, readDuration, func(ctx context.Context) error {
+		result, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{stream, ">"}, // ">" = only new undelivered messages
+			Count:    10,
+			Block:    blockMillis * time.Millisecond,
+		}).Result()
+
+		if err != nil {
+			if err == redis.Nil {
+				return nil // No messages
+			}
+			return err
 		}
-		return nil, err
-	}
 
-	var messages []StreamMessage
-	for _, stream := range result {
-		for _, msg := range stream.Messages {
-			messages = append(messages, StreamMessage{
-				ID:   msg.ID,
-				Data: msg.Values,
-			})
+		for _, stream := range result {
+			for _, msg := range stream.Messages {
+				messages = append(messages, queue.Message{
+					ID:   msg.ID,
+					Data: msg.Values,
+				})
+			}
+		}
+		return nil
+	})
+	return messages, err
+}
+
+// Claim reassigns pending entries idle for at least minIdle to consumer. It first
+// reads their delivery counts via the extended form of XPENDING, so callers can
+// decide whether a message has been retried too many times, then transfers
+// ownership via XCLAIM.
+func (c *Client) Claim(ctx context.Context, stream, group, consumer string, minIdle time.Duration) ([]queue.Message, error) {
+	var messages []queue.Message
+	err := instrumentedOp(ctx, "claim", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+		attribute.String("messaging.redis.consumer_group", group),
+		attribute.String("messaging.redis.consumer", consumer),
+		attribute.Int64("messaging.redis.min_idle_ms", minIdle.Milliseconds()),
+	}, nil, nil, func(ctx context.Context) error {
+		pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream: stream,
+			Group:  group,
+			Idle:   minIdle,
+			Start:  "-",
+			End:    "+",
+			Count:  100,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ids := make([]string, len(pending))
+		deliveryCounts := make(map[string]int64, len(pending))
+		for i, p := range pending {
+			ids[i] = p.ID
+			deliveryCounts[p.ID] = p.RetryCount
+		}
+
+		claimed, err := c.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  minIdle,
+			Messages: ids,
+		}).Result()
+		if err != nil {
+			return err
 		}
-	}
 
-	return messages, nil
+		messages = make([]queue.Message, len(claimed))
+		for i, msg := range claimed {
+			messages[i] = queue.Message{ID: msg.ID, Data: msg.Values, DeliveryCount: deliveryCounts[msg.ID]}
+		}
+		return nil
+	})
+	return messages, err
 }
 
-// AckMessage acknowledges a message
-func (c *Client) AckMessage(ctx context.Context, stream, group, messageID string) error {
-	return c.client.XAck(ctx, stream, group, messageID).Err()
+// Ack acknowledges a message
+func (c *Client) Ack(ctx context.Context, stream, group, messageID string) error {
+	return instrumentedOp(ctx, "ack", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+		attribute.String("messaging.redis.consumer_group", group),
+		attribute.String("messaging.message_id", messageID),
+	}, ackCounter, ackDuration, func(ctx context.Context) error {
+		return c.client.XAck(ctx, stream, group, messageID).Err()
+	})
 }
 
 // PublishResult publishes a result to a Redis stream
@@ -131,18 +415,18 @@ func (c *Client) PublishResult(ctx context.Context, stream string, data interfac
 		return "", err
 	}
 
-	id, err := c.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: stream,
-		Values: map[string]interface{}{
-			"data": string(jsonData),
-		},
-	}).Result()
-
+	var id string
+	err = instrumentedOp(ctx, "publish", []attribute.KeyValue{
+		attribute.String("messaging.redis.stream", stream),
+	}, publishCounter, publishDuration, func(ctx context.Context) error {
+		var err error
+		id, err = c.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: stream,
+			Values: map[string]interface{}{
+				"data": string(jsonData),
+			},
+		}).Result()
+		return err
+	})
 	return id, err
 }
-
-// StreamMessage represents a message from Redis stream
-type StreamMessage struct {
-	ID   string
-	Data map[string]interface{}
-}
@@ -9,21 +9,21 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"github.com/oteldemo/workers/internal/config"
-	"github.com/oteldemo/workers/internal/redis"
+	"github.com/oteldemo/workers/internal/queue"
 )
 
 // Server represents the HTTP server
 type Server struct {
-	cfg         *config.Config
-	redis       *redis.Client
-	httpServer  *http.Server
+	cfg        *config.Config
+	queue      queue.Backend
+	httpServer *http.Server
 }
 
 // NewServer creates a new HTTP server
-func NewServer(cfg *config.Config, redisClient *redis.Client) *Server {
+func NewServer(cfg *config.Config, queueBackend queue.Backend) *Server {
 	return &Server{
 		cfg:   cfg,
-		redis: redisClient,
+		queue: queueBackend,
 	}
 }
 
@@ -63,21 +63,31 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 // healthCheck handles health check requests
 func (s *Server) healthCheck(c *gin.Context) {
-	redisHealthy := s.redis.IsHealthy(c.Request.Context())
+	ctx := c.Request.Context()
+
+	var unhealthyNodes []string
+	healthy := true
+	if reporter, ok := s.queue.(queue.NodeHealthReporter); ok {
+		unhealthyNodes = reporter.UnhealthyNodeAddrs(ctx)
+		healthy = len(unhealthyNodes) == 0
+	} else {
+		healthy = s.queue.IsHealthy(ctx)
+	}
 
 	status := "healthy"
 	httpStatus := http.StatusOK
 
-	if !redisHealthy {
+	if !healthy {
 		status = "unhealthy"
 		httpStatus = http.StatusServiceUnavailable
 	}
 
 	c.JSON(httpStatus, gin.H{
-		"status":        status,
-		"service":       s.cfg.ServiceName,
-		"location":      s.cfg.Location,
-		"redis_healthy": redisHealthy,
+		"status":                status,
+		"service":               s.cfg.ServiceName,
+		"location":              s.cfg.Location,
+		"queue_healthy":         healthy,
+		"queue_unhealthy_nodes": unhealthyNodes,
 	})
 }
 
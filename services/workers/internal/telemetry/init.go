@@ -0,0 +1,162 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	logsdk "log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/oteldemo/workers/internal/config"
+)
+
+// Shutdown flushes and tears down whatever Init started.
+type Shutdown func(context.Context) error
+
+var (
+	initMu      sync.Mutex
+	initialized bool
+	enabled     bool
+)
+
+// Enabled reports whether the last successful Init installed real OTel providers
+// (true) or fell back to the no-op ones (false). Callers can use it to skip work that
+// only matters when telemetry is actually being collected.
+func Enabled() bool {
+	initMu.Lock()
+	defer initMu.Unlock()
+	return enabled
+}
+
+// Init builds the shared resource once and initializes the logger, tracer, and meter
+// against it, then installs an OTel ErrorHandler that forwards SDK-internal errors
+// (failed exports, etc.) to the slog logger instead of leaving them on stderr. It
+// returns a single Shutdown that tears subsystems down in reverse init order,
+// aggregating any errors with errors.Join.
+//
+// Init is idempotent: a second call while already initialized logs a warning and
+// returns a no-op Shutdown instead of starting a second set of providers.
+//
+// If cfg.OtelEnabled is false, or the collector can't be dialed at startup, Init
+// installs no-op tracer/meter providers and a plain stderr text log handler instead of
+// failing - a DNS worker should keep resolving queries (and logging to stderr) even
+// when observability is unavailable.
+func Init(ctx context.Context, cfg *config.Config) (Shutdown, error) {
+	initMu.Lock()
+	defer initMu.Unlock()
+
+	if initialized {
+		log.Printf("Warning: telemetry.Init called again while already initialized; ignoring")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	if !cfg.OtelEnabled {
+		log.Printf("OpenTelemetry disabled (OTEL_SDK_DISABLED=true); using no-op providers")
+		return initNoopLocked(), nil
+	}
+
+	if err := probeCollector(cfg); err != nil {
+		log.Printf("Warning: OpenTelemetry collector unreachable (%v); falling back to no-op providers", err)
+		return initNoopLocked(), nil
+	}
+
+	shutdown, err := initEnabledLocked(ctx, cfg)
+	if err != nil {
+		log.Printf("Warning: failed to initialize OpenTelemetry (%v); falling back to no-op providers", err)
+		return initNoopLocked(), nil
+	}
+
+	return shutdown, nil
+}
+
+// probeCollector does a quick reachability check against an OTLP endpoint before
+// committing to real exporters - otlptracegrpc/otlptracehttp dial lazily, so without
+// this a down collector would only surface as silent background export failures.
+// Non-OTLP backends (jaeger, zipkin, stdout) aren't dialed the same way, so they're
+// left to their own construction errors.
+func probeCollector(cfg *config.Config) error {
+	switch Backend(cfg.OtelTracesBackend) {
+	case BackendOTLPGRPC, BackendOTLPHTTP, "":
+	default:
+		return nil
+	}
+
+	conn, err := net.DialTimeout("tcp", cfg.OtelCollectorEndpoint, 2*time.Second)
+	if err != nil {
+		return fmt.Errorf("dialing collector endpoint %s: %w", cfg.OtelCollectorEndpoint, err)
+	}
+	return conn.Close()
+}
+
+// initEnabledLocked builds the real tracer/logger/meter providers. Callers must hold initMu.
+func initEnabledLocked(ctx context.Context, cfg *config.Config) (Shutdown, error) {
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: building resource: %w", err)
+	}
+
+	shutdownLogger, logger, err := initLogger(ctx, res, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: init logger: %w", err)
+	}
+
+	shutdownTracer, err := initTracer(ctx, res, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: init tracer: %w", err)
+	}
+
+	shutdownMeter, err := initMeter(ctx, res, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: init meter: %w", err)
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Error("OpenTelemetry SDK error", "error", err)
+	}))
+
+	initialized = true
+	enabled = true
+
+	return func(ctx context.Context) error {
+		initMu.Lock()
+		initialized = false
+		enabled = false
+		initMu.Unlock()
+
+		// Reverse of init order: meter, tracer, then logger last so earlier shutdown
+		// errors are still logged through it.
+		return errors.Join(
+			shutdownMeter(ctx),
+			shutdownTracer(ctx),
+			shutdownLogger(ctx),
+		)
+	}, nil
+}
+
+// initNoopLocked installs no-op tracer/meter providers and a plain stderr text logger
+// in place of the OTel log bridge. Only the export path is disabled here - app log
+// lines (worker startup/shutdown, errors) must keep reaching the operator even when
+// telemetry itself is off or unreachable.
+func initNoopLocked() Shutdown {
+	otel.SetTracerProvider(tracenoop.NewTracerProvider())
+	otel.SetMeterProvider(metricnoop.NewMeterProvider())
+	logsdk.SetDefault(logsdk.New(logsdk.NewTextHandler(os.Stderr, nil)))
+
+	initialized = true
+	enabled = false
+
+	return func(context.Context) error {
+		initMu.Lock()
+		initialized = false
+		initMu.Unlock()
+		return nil
+	}
+}
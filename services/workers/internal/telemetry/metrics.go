@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/runtime"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/oteldemo/workers/internal/config"
+)
+
+// meter is the package-wide instrument source for the Counter/Histogram helpers below.
+// The runtime instrumentation registered by InitMeter uses the meter provider directly.
+var meter = otel.Meter("dns-worker")
+
+// InitMeter initializes the OpenTelemetry meter provider and registers Go runtime
+// metrics (GC, goroutines, memory) alongside it.
+func InitMeter(cfg *config.Config) (func(context.Context) error, error) {
+	ctx := context.Background()
+
+	res, err := newResource(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return initMeter(ctx, res, cfg)
+}
+
+// initMeter does the actual meter setup against an already-built resource, so Init can
+// build the resource once and share it across the tracer, logger, and meter.
+func initMeter(ctx context.Context, res *resource.Resource, cfg *config.Config) (func(context.Context) error, error) {
+	opts := append([]otlpmetricgrpc.Option{otlpmetricgrpc.WithEndpoint(cfg.OtelCollectorEndpoint)}, grpcMetricTLSOptions(cfg)...)
+	if len(cfg.OtelExporterHeaders) > 0 {
+		opts = append(opts, otlpmetricgrpc.WithHeaders(cfg.OtelExporterHeaders))
+	}
+
+	metricExporter, err := otlpmetricgrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := sdkmetric.NewPeriodicReader(metricExporter)
+
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(reader),
+	)
+
+	otel.SetMeterProvider(meterProvider)
+
+	if err := runtime.Start(runtime.WithMeterProvider(meterProvider)); err != nil {
+		log.Printf("Failed to start runtime metrics: %v", err)
+	}
+
+	log.Printf("OpenTelemetry meter initialized for service: %s", cfg.ServiceName)
+
+	// Return shutdown function
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		return meterProvider.Shutdown(ctx)
+	}, nil
+}
+
+// grpcMetricTLSOptions mirrors grpcTLSOptions for the metrics exporter.
+func grpcMetricTLSOptions(cfg *config.Config) []otlpmetricgrpc.Option {
+	if cfg.OtelExporterInsecure {
+		return []otlpmetricgrpc.Option{otlpmetricgrpc.WithInsecure()}
+	}
+	if cfg.OtelExporterCertFile != "" {
+		if creds, err := credentials.NewClientTLSFromFile(cfg.OtelExporterCertFile, ""); err == nil {
+			return []otlpmetricgrpc.Option{otlpmetricgrpc.WithTLSCredentials(creds)}
+		}
+		log.Printf("Failed to load OTLP TLS certificate %s, falling back to the system cert pool", cfg.OtelExporterCertFile)
+	}
+	return []otlpmetricgrpc.Option{otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(&tls.Config{}))}
+}
+
+// Counter wraps an otel metric.Int64Counter so worker code can emit business metrics
+// (job counts, etc.) without importing the metric API directly.
+type Counter struct {
+	counter metric.Int64Counter
+}
+
+// NewCounter creates a counter instrument on the package meter.
+func NewCounter(name, description string) (*Counter, error) {
+	counter, err := meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{counter: counter}, nil
+}
+
+// Add records delta against the counter, tagged with attrs.
+func (c *Counter) Add(ctx context.Context, delta int64, attrs ...attribute.KeyValue) {
+	c.counter.Add(ctx, delta, metric.WithAttributes(attrs...))
+}
+
+// Histogram wraps an otel metric.Float64Histogram so worker code can emit business
+// metrics (job durations, etc.) without importing the metric API directly.
+type Histogram struct {
+	histogram metric.Float64Histogram
+}
+
+// NewHistogram creates a histogram instrument on the package meter.
+func NewHistogram(name, description, unit string) (*Histogram, error) {
+	histogram, err := meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit(unit))
+	if err != nil {
+		return nil, err
+	}
+	return &Histogram{histogram: histogram}, nil
+}
+
+// Record records value against the histogram, tagged with attrs.
+func (h *Histogram) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	h.histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+}
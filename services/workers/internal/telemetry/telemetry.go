@@ -2,47 +2,64 @@ package telemetry
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"log"
 	logsdk "log/slog"
+	"os"
 	"time"
 
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/exporters/zipkin"
 	"go.opentelemetry.io/otel/propagation"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	sdklog "go.opentelemetry.io/otel/sdk/log"
 	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"google.golang.org/grpc/credentials"
+
 	"go.opentelemetry.io/contrib/bridges/otelslog"
 
 	"github.com/oteldemo/workers/internal/config"
 )
 
+// Backend selects which exporter implementation a signal is sent through. Not every
+// backend supports every signal - jaeger and zipkin only carry traces, so
+// createLogExporter returns an error if one of them is configured for logs.
+type Backend string
+
+const (
+	BackendOTLPGRPC Backend = "otlpgrpc"
+	BackendOTLPHTTP Backend = "otlphttp"
+	BackendJaeger   Backend = "jaeger"
+	BackendZipkin   Backend = "zipkin"
+	BackendStdout   Backend = "stdout"
+)
+
 // InitTracer initializes the OpenTelemetry tracer
 func InitTracer(cfg *config.Config) (func(context.Context) error, error) {
 	ctx := context.Background()
 
-	// Create resource
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(cfg.ServiceName),
-			semconv.ServiceVersion("1.0.0"),
-			semconv.DeploymentEnvironment("demo"),
-		),
-		resource.WithAttributes(
-			semconv.HostName(cfg.Location),
-		),
-	)
+	res, err := newResource(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create OTLP trace exporter
-	traceExporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithEndpoint(cfg.OtelCollectorEndpoint),
-		otlptracegrpc.WithInsecure(),
-	)
+	return initTracer(ctx, res, cfg)
+}
+
+// initTracer does the actual tracer setup against an already-built resource, so Init
+// can build the resource once and share it across the tracer, logger, and meter.
+func initTracer(ctx context.Context, res *resource.Resource, cfg *config.Config) (func(context.Context) error, error) {
+	traceExporter, err := createSpanExporter(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +69,7 @@ func InitTracer(cfg *config.Config) (func(context.Context) error, error) {
 
 	// Create tracer provider
 	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSampler(buildSampler(cfg)),
 		sdktrace.WithResource(res),
 		sdktrace.WithSpanProcessor(bsp),
 	)
@@ -66,7 +83,7 @@ func InitTracer(cfg *config.Config) (func(context.Context) error, error) {
 		propagation.Baggage{},
 	))
 
-	log.Printf("OpenTelemetry tracer initialized for service: %s", cfg.ServiceName)
+	log.Printf("OpenTelemetry tracer initialized for service: %s (backend: %s)", cfg.ServiceName, cfg.OtelTracesBackend)
 
 	// Return shutdown function
 	return func(ctx context.Context) error {
@@ -80,26 +97,18 @@ func InitTracer(cfg *config.Config) (func(context.Context) error, error) {
 func InitLogger(cfg *config.Config) (func(context.Context) error, *logsdk.Logger, error) {
 	ctx := context.Background()
 
-	// Create resource (same as tracer)
-	res, err := resource.New(ctx,
-		resource.WithAttributes(
-			semconv.ServiceName(cfg.ServiceName),
-			semconv.ServiceVersion("1.0.0"),
-			semconv.DeploymentEnvironment("demo"),
-		),
-		resource.WithAttributes(
-			semconv.HostName(cfg.Location),
-		),
-	)
+	res, err := newResource(ctx, cfg)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// Create OTLP log exporter
-	logExporter, err := otlploggrpc.New(ctx,
-		otlploggrpc.WithEndpoint(cfg.OtelCollectorEndpoint),
-		otlploggrpc.WithInsecure(),
-	)
+	return initLogger(ctx, res, cfg)
+}
+
+// initLogger does the actual logger setup against an already-built resource, so Init
+// can build the resource once and share it across the tracer, logger, and meter.
+func initLogger(ctx context.Context, res *resource.Resource, cfg *config.Config) (func(context.Context) error, *logsdk.Logger, error) {
+	logExporter, err := createLogExporter(ctx, cfg)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -113,16 +122,17 @@ func InitLogger(cfg *config.Config) (func(context.Context) error, *logsdk.Logger
 		sdklog.WithProcessor(logProcessor),
 	)
 
-	// Create slog handler that bridges to OTEL
+	// Create slog handler that bridges to OTEL, gated by OTEL_LOG_LEVEL
 	otelHandler := otelslog.NewHandler(cfg.ServiceName, otelslog.WithLoggerProvider(loggerProvider))
+	leveledHandler := newLevelHandler(parseLogLevel(cfg.OtelLogLevel), otelHandler)
 
 	// Create slog logger with OTEL handler
-	logger := logsdk.New(otelHandler)
+	logger := logsdk.New(leveledHandler)
 
 	// Set as default slog logger
 	logsdk.SetDefault(logger)
 
-	log.Printf("OpenTelemetry logger initialized for service: %s", cfg.ServiceName)
+	log.Printf("OpenTelemetry logger initialized for service: %s (backend: %s)", cfg.ServiceName, cfg.OtelLogsBackend)
 
 	// Return shutdown function and logger
 	return func(ctx context.Context) error {
@@ -131,3 +141,214 @@ func InitLogger(cfg *config.Config) (func(context.Context) error, *logsdk.Logger
 		return loggerProvider.Shutdown(ctx)
 	}, logger, nil
 }
+
+// newResource builds the resource shared by the tracer and logger providers. Extra
+// attributes from OTEL_RESOURCE_ATTRIBUTES are merged in first so the attributes
+// derived from cfg (service name, version, location) still take precedence on
+// conflicting keys.
+func newResource(ctx context.Context, cfg *config.Config) (*resource.Resource, error) {
+	return resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithAttributes(
+			semconv.ServiceName(cfg.ServiceName),
+			semconv.ServiceVersion("1.0.0"),
+			semconv.DeploymentEnvironment("demo"),
+		),
+		resource.WithAttributes(
+			semconv.HostName(cfg.Location),
+		),
+	)
+}
+
+// buildSampler maps cfg.OtelTracesSampler onto an sdktrace.Sampler, following the
+// OTEL_TRACES_SAMPLER value names from the OpenTelemetry spec.
+func buildSampler(cfg *config.Config) sdktrace.Sampler {
+	switch cfg.OtelTracesSampler {
+	case "always_off":
+		return sdktrace.NeverSample()
+	case "traceidratio":
+		return sdktrace.TraceIDRatioBased(cfg.OtelTracesSamplerArg)
+	case "parentbased_always_off":
+		return sdktrace.ParentBased(sdktrace.NeverSample())
+	case "parentbased_traceidratio":
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.OtelTracesSamplerArg))
+	case "parentbased_always_on":
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	default: // "always_on"
+		return sdktrace.AlwaysSample()
+	}
+}
+
+// createSpanExporter constructs the trace exporter selected by cfg.OtelTracesBackend,
+// similar to the switch-on-batcher pattern used to pick a trace agent in go-zero's
+// core/trace/agent.go.
+func createSpanExporter(ctx context.Context, cfg *config.Config) (sdktrace.SpanExporter, error) {
+	switch Backend(cfg.OtelTracesBackend) {
+	case BackendOTLPHTTP:
+		opts := append([]otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OtelCollectorEndpoint)}, httpTLSOptions(cfg)...)
+		if len(cfg.OtelExporterHeaders) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(cfg.OtelExporterHeaders))
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	case BackendJaeger:
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(cfg.OtelCollectorEndpoint)))
+
+	case BackendZipkin:
+		return zipkin.New(cfg.OtelCollectorEndpoint)
+
+	case BackendStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+
+	case BackendOTLPGRPC, "":
+		opts := append([]otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OtelCollectorEndpoint)}, grpcTLSOptions(cfg)...)
+		if len(cfg.OtelExporterHeaders) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(cfg.OtelExporterHeaders))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_TRACES_BACKEND %q", cfg.OtelTracesBackend)
+	}
+}
+
+// createLogExporter constructs the log exporter selected by cfg.OtelLogsBackend.
+// jaeger and zipkin don't carry logs, so they aren't valid values here.
+func createLogExporter(ctx context.Context, cfg *config.Config) (sdklog.Exporter, error) {
+	switch Backend(cfg.OtelLogsBackend) {
+	case BackendOTLPHTTP:
+		opts := append([]otlploghttp.Option{otlploghttp.WithEndpoint(cfg.OtelCollectorEndpoint)}, httpLogTLSOptions(cfg)...)
+		if len(cfg.OtelExporterHeaders) > 0 {
+			opts = append(opts, otlploghttp.WithHeaders(cfg.OtelExporterHeaders))
+		}
+		return otlploghttp.New(ctx, opts...)
+
+	case BackendStdout:
+		return stdoutlog.New()
+
+	case BackendOTLPGRPC, "":
+		opts := append([]otlploggrpc.Option{otlploggrpc.WithEndpoint(cfg.OtelCollectorEndpoint)}, grpcLogTLSOptions(cfg)...)
+		if len(cfg.OtelExporterHeaders) > 0 {
+			opts = append(opts, otlploggrpc.WithHeaders(cfg.OtelExporterHeaders))
+		}
+		return otlploggrpc.New(ctx, opts...)
+
+	default:
+		return nil, fmt.Errorf("unsupported OTEL_LOGS_BACKEND %q", cfg.OtelLogsBackend)
+	}
+}
+
+// grpcTLSOptions resolves cfg's TLS settings into otlptracegrpc dial credentials:
+// plaintext when OtelExporterInsecure is set, a specific CA cert if given, or the
+// system cert pool otherwise.
+func grpcTLSOptions(cfg *config.Config) []otlptracegrpc.Option {
+	if cfg.OtelExporterInsecure {
+		return []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+	}
+	if cfg.OtelExporterCertFile != "" {
+		if creds, err := credentials.NewClientTLSFromFile(cfg.OtelExporterCertFile, ""); err == nil {
+			return []otlptracegrpc.Option{otlptracegrpc.WithTLSCredentials(creds)}
+		}
+		log.Printf("Failed to load OTLP TLS certificate %s, falling back to the system cert pool", cfg.OtelExporterCertFile)
+	}
+	return []otlptracegrpc.Option{otlptracegrpc.WithTLSCredentials(credentials.NewTLS(&tls.Config{}))}
+}
+
+func grpcLogTLSOptions(cfg *config.Config) []otlploggrpc.Option {
+	if cfg.OtelExporterInsecure {
+		return []otlploggrpc.Option{otlploggrpc.WithInsecure()}
+	}
+	if cfg.OtelExporterCertFile != "" {
+		if creds, err := credentials.NewClientTLSFromFile(cfg.OtelExporterCertFile, ""); err == nil {
+			return []otlploggrpc.Option{otlploggrpc.WithTLSCredentials(creds)}
+		}
+		log.Printf("Failed to load OTLP TLS certificate %s, falling back to the system cert pool", cfg.OtelExporterCertFile)
+	}
+	return []otlploggrpc.Option{otlploggrpc.WithTLSCredentials(credentials.NewTLS(&tls.Config{}))}
+}
+
+func httpTLSOptions(cfg *config.Config) []otlptracehttp.Option {
+	if cfg.OtelExporterInsecure {
+		return []otlptracehttp.Option{otlptracehttp.WithInsecure()}
+	}
+	tlsConfig := &tls.Config{}
+	if cfg.OtelExporterCertFile != "" {
+		if pool, err := certPoolFromFile(cfg.OtelExporterCertFile); err == nil {
+			tlsConfig.RootCAs = pool
+		} else {
+			log.Printf("Failed to load OTLP TLS certificate %s, falling back to the system cert pool", cfg.OtelExporterCertFile)
+		}
+	}
+	return []otlptracehttp.Option{otlptracehttp.WithTLSClientConfig(tlsConfig)}
+}
+
+func httpLogTLSOptions(cfg *config.Config) []otlploghttp.Option {
+	if cfg.OtelExporterInsecure {
+		return []otlploghttp.Option{otlploghttp.WithInsecure()}
+	}
+	tlsConfig := &tls.Config{}
+	if cfg.OtelExporterCertFile != "" {
+		if pool, err := certPoolFromFile(cfg.OtelExporterCertFile); err == nil {
+			tlsConfig.RootCAs = pool
+		} else {
+			log.Printf("Failed to load OTLP TLS certificate %s, falling back to the system cert pool", cfg.OtelExporterCertFile)
+		}
+	}
+	return []otlploghttp.Option{otlploghttp.WithTLSClientConfig(tlsConfig)}
+}
+
+// parseLogLevel maps an OTEL_LOG_LEVEL value onto a slog.Level, following the level
+// names the OpenTelemetry spec borrows from syslog. Unrecognized values default to info.
+func parseLogLevel(level string) logsdk.Level {
+	switch level {
+	case "debug":
+		return logsdk.LevelDebug
+	case "warn", "warning":
+		return logsdk.LevelWarn
+	case "error":
+		return logsdk.LevelError
+	default: // "info"
+		return logsdk.LevelInfo
+	}
+}
+
+// levelHandler wraps a slog.Handler and gates it on a minimum level, so OTEL_LOG_LEVEL
+// can filter out records before they reach the (comparatively expensive) OTel bridge.
+type levelHandler struct {
+	min     logsdk.Level
+	handler logsdk.Handler
+}
+
+func newLevelHandler(min logsdk.Level, handler logsdk.Handler) logsdk.Handler {
+	return &levelHandler{min: min, handler: handler}
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level logsdk.Level) bool {
+	return level >= h.min && h.handler.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, record logsdk.Record) error {
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *levelHandler) WithAttrs(attrs []logsdk.Attr) logsdk.Handler {
+	return &levelHandler{min: h.min, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *levelHandler) WithGroup(name string) logsdk.Handler {
+	return &levelHandler{min: h.min, handler: h.handler.WithGroup(name)}
+}
+
+// certPoolFromFile loads a single PEM-encoded CA certificate into its own pool, used
+// to verify the collector/backend's TLS certificate when OtelExporterCertFile is set.
+func certPoolFromFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -16,23 +17,41 @@ import (
 
 	"github.com/oteldemo/workers/internal/config"
 	"github.com/oteldemo/workers/internal/dns"
-	"github.com/oteldemo/workers/internal/redis"
+	"github.com/oteldemo/workers/internal/queue"
+	"github.com/oteldemo/workers/internal/telemetry"
 )
 
 var tracer = otel.Tracer("dns-worker")
 
+var (
+	tasksProcessed *telemetry.Counter
+	taskDuration   *telemetry.Histogram
+)
+
+func init() {
+	var err error
+	if tasksProcessed, err = telemetry.NewCounter("dns_worker.tasks_processed", "Number of DNS lookup tasks processed, tagged by status"); err != nil {
+		log.Printf("Failed to create tasks_processed counter: %v", err)
+	}
+	if taskDuration, err = telemetry.NewHistogram("dns_worker.task_duration", "Duration of DNS lookup task processing", "ms"); err != nil {
+		log.Printf("Failed to create task_duration histogram: %v", err)
+	}
+}
+
 // Worker processes DNS lookup tasks
 type Worker struct {
 	cfg         *config.Config
-	redis       *redis.Client
+	queue       queue.Backend
 	dnsResolver *dns.Resolver
 }
 
-// NewWorker creates a new worker
-func NewWorker(cfg *config.Config, redisClient *redis.Client, dnsResolver *dns.Resolver) *Worker {
+// NewWorker creates a new worker. queueBackend may be backed by Redis Streams, an
+// embedded LevelDB queue, or an in-memory queue for tests - worker logic doesn't
+// depend on which.
+func NewWorker(cfg *config.Config, queueBackend queue.Backend, dnsResolver *dns.Resolver) *Worker {
 	return &Worker{
 		cfg:         cfg,
-		redis:       redisClient,
+		queue:       queueBackend,
 		dnsResolver: dnsResolver,
 	}
 }
@@ -41,13 +60,24 @@ func NewWorker(cfg *config.Config, redisClient *redis.Client, dnsResolver *dns.R
 func (w *Worker) Start(ctx context.Context) error {
 	log.Printf("Worker starting for location: %s", w.cfg.Location)
 
+	if reporter, ok := w.queue.(queue.NodeHealthReporter); ok {
+		if unhealthy := reporter.UnhealthyNodeAddrs(ctx); len(unhealthy) > 0 {
+			log.Printf("Warning: queue backend nodes unreachable at startup: %v", unhealthy)
+		}
+	} else if !w.queue.IsHealthy(ctx) {
+		log.Printf("Warning: queue backend unreachable at startup")
+	}
+
 	// Create consumer group
-	if err := w.redis.CreateConsumerGroup(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup); err != nil {
+	if err := w.queue.EnsureGroup(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup); err != nil {
 		return err
 	}
 
 	consumerName := "consumer-" + w.cfg.Location
 
+	// Reclaim messages left pending by consumers that died before acknowledging
+	go w.reclaimLoop(ctx, consumerName)
+
 	// Main processing loop
 	for {
 		select {
@@ -57,7 +87,7 @@ func (w *Worker) Start(ctx context.Context) error {
 		default:
 			// First, check for pending messages (delivered but not acknowledged)
 			// This ensures no messages are lost if worker crashes/restarts
-			pendingMessages, err := w.redis.ReadPendingMessages(
+			pendingMessages, err := w.queue.ReadPending(
 				ctx,
 				w.cfg.TasksStream,
 				w.cfg.ConsumerGroup,
@@ -70,14 +100,14 @@ func (w *Worker) Start(ctx context.Context) error {
 
 			// Process pending messages first
 			for _, msg := range pendingMessages {
-				w.processMessage(ctx, msg)
-				if err := w.redis.AckMessage(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup, msg.ID); err != nil {
+				msgCtx := w.processMessage(ctx, msg)
+				if err := w.queue.Ack(msgCtx, w.cfg.TasksStream, w.cfg.ConsumerGroup, msg.ID); err != nil {
 					log.Printf("Error acknowledging pending message: %v", err)
 				}
 			}
 
 			// Then read new messages from stream
-			messages, err := w.redis.ReadFromStream(
+			messages, err := w.queue.ReadNew(
 				ctx,
 				w.cfg.TasksStream,
 				w.cfg.ConsumerGroup,
@@ -95,10 +125,10 @@ func (w *Worker) Start(ctx context.Context) error {
 
 			// Process each new message
 			for _, msg := range messages {
-				w.processMessage(ctx, msg)
+				msgCtx := w.processMessage(ctx, msg)
 
 				// Acknowledge message
-				if err := w.redis.AckMessage(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup, msg.ID); err != nil {
+				if err := w.queue.Ack(msgCtx, w.cfg.TasksStream, w.cfg.ConsumerGroup, msg.ID); err != nil {
 					log.Printf("Error acknowledging message: %v", err)
 				}
 			}
@@ -106,21 +136,98 @@ func (w *Worker) Start(ctx context.Context) error {
 	}
 }
 
-// processMessage processes a single DNS lookup task
-func (w *Worker) processMessage(ctx context.Context, msg redis.StreamMessage) {
+// reclaimLoop periodically steals pending messages that have been idle longer than
+// w.cfg.ReclaimMinIdleTime, so a crashed worker pod doesn't leave tasks stuck forever.
+func (w *Worker) reclaimLoop(ctx context.Context, consumerName string) {
+	ticker := time.NewTicker(w.cfg.ReclaimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reclaimStaleMessages(ctx, consumerName)
+		}
+	}
+}
+
+// reclaimStaleMessages claims stale pending entries for consumerName. Entries that
+// have already exceeded w.cfg.ReclaimMaxDeliveries are diverted straight to the DLQ
+// instead of being handed back for reprocessing.
+func (w *Worker) reclaimStaleMessages(ctx context.Context, consumerName string) {
+	claimed, err := w.queue.Claim(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup, consumerName, w.cfg.ReclaimMinIdleTime)
+	if err != nil {
+		if !strings.Contains(err.Error(), "i/o timeout") {
+			log.Printf("Error claiming stale messages: %v", err)
+		}
+		return
+	}
+
+	for _, msg := range claimed {
+		deliveryCount := msg.DeliveryCount
+
+		spanCtx, span := tracer.Start(ctx, "reclaim_pending_message",
+			trace.WithAttributes(
+				attribute.String("message.id", msg.ID),
+				attribute.Int64("message.delivery_count", deliveryCount),
+			),
+		)
+
+		if deliveryCount > w.cfg.ReclaimMaxDeliveries {
+			span.AddEvent("diverted_to_dlq")
+			w.deadLetterMessage(spanCtx, msg, deliveryCount)
+		} else {
+			span.AddEvent("reclaimed")
+			log.Printf("Reclaimed stale message %s from a dead consumer (delivery attempt %d)", msg.ID, deliveryCount)
+		}
+
+		span.End()
+	}
+}
+
+// deadLetterMessage publishes a stuck message to the DLQ stream along with the reason
+// and original message ID, then acknowledges it so it stops circulating.
+func (w *Worker) deadLetterMessage(ctx context.Context, msg queue.Message, deliveryCount int64) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	entry := DeadLetterEntry{
+		OriginalMessageID: msg.ID,
+		Reason:            fmt.Sprintf("exceeded max deliveries (%d > %d)", deliveryCount, w.cfg.ReclaimMaxDeliveries),
+		DeliveryCount:     deliveryCount,
+		Data:              msg.Data,
+		TraceContext:      carrier,
+	}
+
+	if _, err := w.queue.PublishResult(ctx, w.cfg.DLQStream, entry); err != nil {
+		log.Printf("Error publishing message %s to DLQ: %v", msg.ID, err)
+		return
+	}
+
+	if err := w.queue.Ack(ctx, w.cfg.TasksStream, w.cfg.ConsumerGroup, msg.ID); err != nil {
+		log.Printf("Error acknowledging dead-lettered message %s: %v", msg.ID, err)
+	}
+}
+
+// processMessage processes a single DNS lookup task and returns the process_dns_task
+// span's context, so the caller can Ack the message as a child of that span instead of
+// the enclosing Start() context. A message whose data can't be parsed as a Task is
+// dropped without publishing a result, and ctx is returned unchanged.
+func (w *Worker) processMessage(ctx context.Context, msg queue.Message) context.Context {
 	start := time.Now()
 
 	// Extract task data
 	dataJSON, ok := msg.Data["data"].(string)
 	if !ok {
 		log.Printf("Invalid message format: %v", msg.Data)
-		return
+		return ctx
 	}
 
 	var task Task
 	if err := json.Unmarshal([]byte(dataJSON), &task); err != nil {
 		log.Printf("Error parsing task: %v", err)
-		return
+		return ctx
 	}
 
 	// No filtering needed - each worker receives messages via its own consumer group
@@ -147,7 +254,7 @@ func (w *Worker) processMessage(ctx context.Context, msg redis.StreamMessage) {
 			attribute.String("task.id", task.TaskID),
 			attribute.String("trace.id", task.TraceID),
 			attribute.String("dns.domain", task.Domain),
-			attribute.String("worker.location", w.cfg.Location),  // Use worker's configured location
+			attribute.String("worker.location", w.cfg.Location), // Use worker's configured location
 		),
 	)
 	defer span.End()
@@ -161,7 +268,7 @@ func (w *Worker) processMessage(ctx context.Context, msg redis.StreamMessage) {
 	result := Result{
 		TaskID:           task.TaskID,
 		TraceID:          task.TraceID,
-		Location:         w.cfg.Location,  // Use worker's configured location
+		Location:         w.cfg.Location, // Use worker's configured location
 		Domain:           task.Domain,
 		Status:           "success",
 		Records:          results,
@@ -190,8 +297,15 @@ func (w *Worker) processMessage(ctx context.Context, msg redis.StreamMessage) {
 		attribute.Float64("processing_time_ms", result.ProcessingTimeMs),
 	)
 
+	if tasksProcessed != nil {
+		tasksProcessed.Add(ctx, 1, attribute.String("status", result.Status), attribute.String("location", w.cfg.Location))
+	}
+	if taskDuration != nil {
+		taskDuration.Record(ctx, result.ProcessingTimeMs, attribute.String("location", w.cfg.Location))
+	}
+
 	// Publish result
-	if _, err := w.redis.PublishResult(ctx, w.cfg.ResultsStream, result); err != nil {
+	if _, err := w.queue.PublishResult(ctx, w.cfg.ResultsStream, result); err != nil {
 		log.Printf("Error publishing result: %v", err)
 		span.SetAttributes(
 			attribute.Bool("error", true),
@@ -200,12 +314,14 @@ func (w *Worker) processMessage(ctx context.Context, msg redis.StreamMessage) {
 	} else {
 		log.Printf("Published result for task %s (processing time: %dms)", task.TaskID, processingTime.Milliseconds())
 	}
+
+	return ctx
 }
 
 // Task represents a DNS lookup task from Redis stream
 // Note: All workers receive the same task via separate consumer groups (fan-out pattern)
 type Task struct {
-	TraceID      string            `json:"trace_id"`        // OpenTelemetry trace ID for correlation
+	TraceID      string            `json:"trace_id"` // OpenTelemetry trace ID for correlation
 	TaskID       string            `json:"task_id"`
 	Domain       string            `json:"domain"`
 	Location     string            `json:"location,omitempty"` // Not used - each worker uses its own configured location
@@ -225,3 +341,13 @@ type Result struct {
 	Error            string                      `json:"error,omitempty"`
 	ProcessingTimeMs float64                     `json:"processing_time_ms"`
 }
+
+// DeadLetterEntry is published to the DLQ stream when a pending task exceeds
+// cfg.ReclaimMaxDeliveries without ever being acknowledged.
+type DeadLetterEntry struct {
+	OriginalMessageID string                 `json:"original_message_id"`
+	Reason            string                 `json:"reason"`
+	DeliveryCount     int64                  `json:"delivery_count"`
+	Data              map[string]interface{} `json:"data"`
+	TraceContext      map[string]string      `json:"trace_context,omitempty"`
+}
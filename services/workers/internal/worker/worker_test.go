@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/oteldemo/workers/internal/config"
+	"github.com/oteldemo/workers/internal/dns"
+	"github.com/oteldemo/workers/internal/memqueue"
+	"github.com/oteldemo/workers/internal/queue"
+)
+
+func TestProcessMessagePublishesResult(t *testing.T) {
+	cfg := &config.Config{
+		Location:      "test",
+		TasksStream:   "dns:tasks",
+		ResultsStream: "dns:results",
+		DLQStream:     "dns:tasks:dlq",
+		ConsumerGroup: "workers-test",
+		// 192.0.2.1 is the RFC 5737 TEST-NET-1 address: guaranteed non-routable, so the
+		// lookup fails fast and deterministically instead of depending on the sandbox's
+		// network access.
+		DNSUpstreamServer: "192.0.2.1:53",
+		DNSTimeout:        50 * time.Millisecond,
+	}
+
+	backend := memqueue.New()
+	w := NewWorker(cfg, backend, dns.NewResolver(cfg))
+
+	ctx := context.Background()
+	if err := backend.EnsureGroup(ctx, cfg.ResultsStream, "test-readers"); err != nil {
+		t.Fatalf("EnsureGroup: %v", err)
+	}
+
+	task := Task{
+		TaskID:      "task-1",
+		TraceID:     "trace-1",
+		Domain:      "example.com",
+		RecordTypes: []string{"A"},
+	}
+	dataJSON, err := json.Marshal(task)
+	if err != nil {
+		t.Fatalf("marshal task: %v", err)
+	}
+	msg := queue.Message{
+		ID:   "1",
+		Data: map[string]interface{}{"data": string(dataJSON)},
+	}
+
+	resultCtx := w.processMessage(ctx, msg)
+	if resultCtx == nil {
+		t.Fatal("processMessage returned a nil context")
+	}
+
+	published, err := backend.ReadNew(ctx, cfg.ResultsStream, "test-readers", "test-consumer")
+	if err != nil {
+		t.Fatalf("ReadNew: %v", err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("got %d published results, want 1", len(published))
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(published[0].Data["data"].(string)), &result); err != nil {
+		t.Fatalf("unmarshal result: %v", err)
+	}
+	if result.TaskID != task.TaskID {
+		t.Errorf("result.TaskID = %q, want %q", result.TaskID, task.TaskID)
+	}
+	if result.Status != "failed" {
+		t.Errorf("result.Status = %q, want %q (unroutable upstream should fail the lookup)", result.Status, "failed")
+	}
+}
+
+func TestProcessMessageInvalidDataIsDropped(t *testing.T) {
+	cfg := &config.Config{
+		Location:      "test",
+		TasksStream:   "dns:tasks",
+		ResultsStream: "dns:results",
+		ConsumerGroup: "workers-test",
+	}
+
+	backend := memqueue.New()
+	w := NewWorker(cfg, backend, dns.NewResolver(cfg))
+
+	ctx := context.Background()
+	if err := backend.EnsureGroup(ctx, cfg.ResultsStream, "test-readers"); err != nil {
+		t.Fatalf("EnsureGroup: %v", err)
+	}
+
+	msg := queue.Message{ID: "1", Data: map[string]interface{}{}}
+	if resultCtx := w.processMessage(ctx, msg); resultCtx != ctx {
+		t.Error("processMessage should return the input ctx unchanged for a malformed message")
+	}
+
+	published, err := backend.ReadNew(ctx, cfg.ResultsStream, "test-readers", "test-consumer")
+	if err != nil {
+		t.Fatalf("ReadNew: %v", err)
+	}
+	if len(published) != 0 {
+		t.Fatalf("got %d published results for an invalid message, want 0", len(published))
+	}
+}